@@ -0,0 +1,238 @@
+/*
+ * SPDX-License-Identifier: MIT
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package logs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type overflowPolicy int8
+
+const (
+	overflowBlock overflowPolicy = iota
+	overflowDropOldest
+	overflowDropNewest
+)
+
+func parseOverflowPolicy(spec string) (overflowPolicy, error) {
+	switch spec {
+	case "", "block":
+		return overflowBlock, nil
+	case "drop_oldest":
+		return overflowDropOldest, nil
+	case "drop_newest":
+		return overflowDropNewest, nil
+	default:
+		return 0, fmt.Errorf("logs: invalid overflow_policy %q", spec)
+	}
+}
+
+// asyncWriter decouples a sink's underlying writer from the logging call
+// site: records are pushed onto a bounded channel and written by a
+// background goroutine, which flushes on flushInterval. It does not close
+// the underlying writer.
+type asyncWriter struct {
+	underlying io.Writer
+	bw         *bufio.Writer
+	policy     overflowPolicy
+
+	records  chan []byte
+	flushReq chan chan struct{}
+	closeCh  chan struct{}
+	doneCh   chan struct{}
+
+	flushInterval time.Duration
+}
+
+func newAsyncWriter(underlying io.Writer, bufferSize int, flushInterval time.Duration, policy overflowPolicy) *asyncWriter {
+	if bufferSize <= 0 {
+		bufferSize = 1024
+	}
+	w := &asyncWriter{
+		underlying:    underlying,
+		bw:            bufio.NewWriter(underlying),
+		policy:        policy,
+		records:       make(chan []byte, bufferSize),
+		flushReq:      make(chan chan struct{}),
+		closeCh:       make(chan struct{}),
+		doneCh:        make(chan struct{}),
+		flushInterval: flushInterval,
+	}
+	go w.run()
+	return w
+}
+
+func (w *asyncWriter) run() {
+	defer close(w.doneCh)
+
+	var tickerC <-chan time.Time
+	if w.flushInterval > 0 {
+		ticker := time.NewTicker(w.flushInterval)
+		defer ticker.Stop()
+		tickerC = ticker.C
+	}
+
+	for {
+		select {
+		case buf := <-w.records:
+			_, _ = w.bw.Write(buf)
+		case req := <-w.flushReq:
+			_ = w.bw.Flush()
+			close(req)
+		case <-tickerC:
+			_ = w.bw.Flush()
+		case <-w.closeCh:
+			w.drain()
+			return
+		}
+	}
+}
+
+// drain flushes every record still queued before the writer stops.
+func (w *asyncWriter) drain() {
+	for {
+		select {
+		case buf := <-w.records:
+			_, _ = w.bw.Write(buf)
+		default:
+			_ = w.bw.Flush()
+			return
+		}
+	}
+}
+
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	buf := make([]byte, len(p))
+	copy(buf, p)
+
+	switch w.policy {
+	case overflowDropNewest:
+		select {
+		case w.records <- buf:
+		default:
+			atomic.AddUint64(&droppedCount, 1)
+		}
+	case overflowDropOldest:
+		for {
+			select {
+			case w.records <- buf:
+				return len(p), nil
+			default:
+			}
+			select {
+			case <-w.records:
+				atomic.AddUint64(&droppedCount, 1)
+			default:
+			}
+		}
+	default: // overflowBlock
+		w.records <- buf
+	}
+	return len(p), nil
+}
+
+// Sync blocks until every record queued so far has been flushed to the
+// underlying writer, and, if the underlying writer is itself a syncer
+// (e.g. a rotatingFile), fsyncs it too.
+func (w *asyncWriter) Sync() error {
+	done := make(chan struct{})
+	select {
+	case w.flushReq <- done:
+		<-done
+	case <-w.doneCh:
+	}
+	if s, ok := w.underlying.(syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Close stops the background goroutine after draining and flushing every
+// queued record. It does not close the underlying writer.
+func (w *asyncWriter) Close() error {
+	select {
+	case <-w.doneCh:
+	default:
+		close(w.closeCh)
+	}
+	<-w.doneCh
+	return nil
+}
+
+var (
+	asyncWritersMu      sync.Mutex
+	currentAsyncWriters []*asyncWriter
+
+	droppedCount uint64
+)
+
+// DroppedCount reports how many records have been dropped across all async
+// sinks configured with overflow_policy drop_oldest or drop_newest.
+func DroppedCount() uint64 {
+	return atomic.LoadUint64(&droppedCount)
+}
+
+// swapAsyncWriters installs writers as the active set backing the current
+// sinks and returns whatever set they replace, so the caller can drain and
+// close it once the new sinks are live.
+func swapAsyncWriters(writers []*asyncWriter) []*asyncWriter {
+	asyncWritersMu.Lock()
+	old := currentAsyncWriters
+	currentAsyncWriters = writers
+	asyncWritersMu.Unlock()
+	return old
+}
+
+func closeAsyncWriters(writers []*asyncWriter) {
+	for _, w := range writers {
+		_ = w.Close()
+	}
+}
+
+// Shutdown drains and closes every active async sink, flushing all queued
+// records so none are lost. Call it once before process exit.
+func Shutdown(ctx context.Context) error {
+	asyncWritersMu.Lock()
+	writers := currentAsyncWriters
+	currentAsyncWriters = nil
+	asyncWritersMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		closeAsyncWriters(writers)
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,135 @@
+/*
+ * SPDX-License-Identifier: MIT
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestParseOverflowPolicy(t *testing.T) {
+	cases := []struct {
+		spec    string
+		want    overflowPolicy
+		wantErr bool
+	}{
+		{"", overflowBlock, false},
+		{"block", overflowBlock, false},
+		{"drop_oldest", overflowDropOldest, false},
+		{"drop_newest", overflowDropNewest, false},
+		{"bogus", 0, true},
+	}
+	for _, c := range cases {
+		got, err := parseOverflowPolicy(c.spec)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("parseOverflowPolicy(%q): expected error", c.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseOverflowPolicy(%q): unexpected error: %v", c.spec, err)
+		}
+		if got != c.want {
+			t.Errorf("parseOverflowPolicy(%q) = %v, want %v", c.spec, got, c.want)
+		}
+	}
+}
+
+// newTestAsyncWriter builds an asyncWriter without starting its background
+// goroutine, so the records channel fills deterministically and overflow
+// behavior can be asserted directly against Write.
+func newTestAsyncWriter(policy overflowPolicy, bufferSize int) *asyncWriter {
+	return &asyncWriter{
+		underlying: io.Discard,
+		bw:         bufio.NewWriter(io.Discard),
+		policy:     policy,
+		records:    make(chan []byte, bufferSize),
+		flushReq:   make(chan chan struct{}),
+		closeCh:    make(chan struct{}),
+		doneCh:     make(chan struct{}),
+	}
+}
+
+func TestAsyncWriterDropNewestOnOverflow(t *testing.T) {
+	w := newTestAsyncWriter(overflowDropNewest, 1)
+	w.records <- []byte("first")
+
+	before := DroppedCount()
+	if _, err := w.Write([]byte("second")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if got := DroppedCount(); got != before+1 {
+		t.Errorf("DroppedCount() = %d, want %d", got, before+1)
+	}
+	if got := <-w.records; string(got) != "first" {
+		t.Errorf("queued record = %q, want %q (oldest kept)", got, "first")
+	}
+}
+
+func TestAsyncWriterDropOldestOnOverflow(t *testing.T) {
+	w := newTestAsyncWriter(overflowDropOldest, 1)
+	w.records <- []byte("first")
+
+	before := DroppedCount()
+	if _, err := w.Write([]byte("second")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if got := DroppedCount(); got != before+1 {
+		t.Errorf("DroppedCount() = %d, want %d", got, before+1)
+	}
+	if got := <-w.records; string(got) != "second" {
+		t.Errorf("queued record = %q, want %q (newest kept)", got, "second")
+	}
+}
+
+func TestAsyncWriterWriteUnderCapacityDoesNotDrop(t *testing.T) {
+	w := newTestAsyncWriter(overflowDropNewest, 2)
+	before := DroppedCount()
+	if _, err := w.Write([]byte("ok")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if got := DroppedCount(); got != before {
+		t.Errorf("DroppedCount() changed unexpectedly: %d -> %d", before, got)
+	}
+	if got := <-w.records; string(got) != "ok" {
+		t.Errorf("queued record = %q, want %q", got, "ok")
+	}
+}
+
+func TestAsyncWriterRunFlushesAndCloses(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := newAsyncWriter(buf, 4, 0, overflowBlock)
+	if _, err := w.Write([]byte("hello")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if buf.String() != "hello" {
+		t.Errorf("underlying content = %q, want %q", buf.String(), "hello")
+	}
+}
@@ -26,9 +26,10 @@ package logs
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
-	"log"
 	"os"
+	"time"
 )
 
 type StreamType int8
@@ -43,16 +44,31 @@ const (
 type ConfigItem struct {
 	Level      LogLevel     `json:"level"`
 	StreamType StreamType   `json:"stream_type"`
+	Encoder    string       `json:"encoder,omitempty"`
 	Params     ConfigParams `json:"params,omitempty"`
 }
 
 type Config struct {
-	Items []*ConfigItem
+	Items     []*ConfigItem
+	Verbosity int32  `json:"verbosity,omitempty"`
+	VModule   string `json:"vmodule,omitempty"`
 }
 
 type ConfigParams struct {
 	Filename string `json:"filename,omitempty"`
 	Perm     *int32 `json:"perm,omitempty"`
+
+	MaxSizeMB      int64  `json:"max_size_mb,omitempty"`
+	MaxBackups     int    `json:"max_backups,omitempty"`
+	MaxAgeDays     int    `json:"max_age_days,omitempty"`
+	RotateInterval string `json:"rotate_interval,omitempty"`
+	Compress       string `json:"compress,omitempty"`
+	Symlink        bool   `json:"symlink,omitempty"`
+
+	Async          bool   `json:"async,omitempty"`
+	BufferSize     int    `json:"buffer_size,omitempty"`
+	FlushInterval  string `json:"flush_interval,omitempty"`
+	OverflowPolicy string `json:"overflow_policy,omitempty"`
 }
 
 func LoadConfig(str []byte) (*Config, error) {
@@ -66,8 +82,21 @@ func LoadConfig(str []byte) (*Config, error) {
 }
 
 func UseConfig(config *Config) error {
-	var debugWriters, infoWriters, warnWriters, errorWriters []io.Writer
+	SetVerbosity(config.Verbosity)
+	if config.VModule != "" {
+		if err := SetVModule(config.VModule); err != nil {
+			return err
+		}
+	}
+
+	var newDebugSinks, newInfoSinks, newWarnSinks, newErrorSinks, newFatalSinks []sink
+	var newAsyncWriters []*asyncWriter
 	for _, item := range config.Items {
+		encoder, err := encoderFor(item.Encoder)
+		if err != nil {
+			return err
+		}
+
 		var writer io.Writer
 		switch item.StreamType {
 		case StreamTypeDiscard:
@@ -88,32 +117,78 @@ func UseConfig(config *Config) error {
 				permInt = *perm
 			}
 
-			file, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, os.FileMode(permInt))
+			rotateInterval, err := parseRotateInterval(item.Params.RotateInterval)
+			if err != nil {
+				return err
+			}
+			var compressAfter time.Duration
+			if item.Params.Compress != "" {
+				compressAfter, err = time.ParseDuration(item.Params.Compress)
+				if err != nil {
+					return fmt.Errorf("logs: invalid compress duration %q: %w", item.Params.Compress, err)
+				}
+			}
+
+			rf, err := getRotatingFile(filename, rotateConfig{
+				Perm:           os.FileMode(permInt),
+				MaxSizeBytes:   item.Params.MaxSizeMB * 1024 * 1024,
+				MaxBackups:     item.Params.MaxBackups,
+				MaxAgeDays:     item.Params.MaxAgeDays,
+				RotateInterval: rotateInterval,
+				CompressAfter:  compressAfter,
+				Symlink:        item.Params.Symlink,
+			})
 			if err != nil {
 				return err
 			}
 
-			writer = file
+			writer = rf
 		}
+
+		if item.Params.Async {
+			flushInterval := 30 * time.Second
+			if item.Params.FlushInterval != "" {
+				flushInterval, err = time.ParseDuration(item.Params.FlushInterval)
+				if err != nil {
+					return fmt.Errorf("logs: invalid flush_interval %q: %w", item.Params.FlushInterval, err)
+				}
+			}
+			policy, err := parseOverflowPolicy(item.Params.OverflowPolicy)
+			if err != nil {
+				return err
+			}
+
+			aw := newAsyncWriter(writer, item.Params.BufferSize, flushInterval, policy)
+			newAsyncWriters = append(newAsyncWriters, aw)
+			writer = aw
+		}
+
+		s := sink{writer: writer, encoder: encoder}
 		switch item.Level {
 		case AllLevels:
 			fallthrough
 		case DebugLevel:
-			debugWriters = append(debugWriters, writer)
+			newDebugSinks = append(newDebugSinks, s)
 			fallthrough
 		case InfoLevel:
-			infoWriters = append(infoWriters, writer)
+			newInfoSinks = append(newInfoSinks, s)
 			fallthrough
 		case WarnLevel:
-			warnWriters = append(warnWriters, writer)
+			newWarnSinks = append(newWarnSinks, s)
 			fallthrough
 		case ErrorLevel:
-			errorWriters = append(errorWriters, writer)
+			newErrorSinks = append(newErrorSinks, s)
+			fallthrough
+		case FatalLevel:
+			newFatalSinks = append(newFatalSinks, s)
 		}
 	}
-	debugLog = log.New(io.MultiWriter(debugWriters...), "DEBUG ", log.Ltime|log.Llongfile)
-	infoLog = log.New(io.MultiWriter(infoWriters...), "INFO  ", log.Ltime|log.Llongfile)
-	warnLog = log.New(io.MultiWriter(warnWriters...), "WARN  ", log.Ltime|log.Llongfile)
-	errorLog = log.New(io.MultiWriter(errorWriters...), "ERROR ", log.Ltime|log.Llongfile)
+	debugSinks = newDebugSinks
+	infoSinks = newInfoSinks
+	warnSinks = newWarnSinks
+	errorSinks = newErrorSinks
+	fatalSinks = newFatalSinks
+
+	closeAsyncWriters(swapAsyncWriters(newAsyncWriters))
 	return nil
 }
@@ -0,0 +1,125 @@
+/*
+ * SPDX-License-Identifier: MIT
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package logs
+
+import "context"
+
+type contextKey int
+
+const (
+	kvsContextKey contextKey = iota
+	loggerContextKey
+)
+
+// WithValues returns a context carrying kvs merged after any values already
+// attached by an earlier WithValues call, so they are automatically
+// included in every Info/Warn/Error/Debug call made with that context.
+func WithValues(ctx context.Context, kvs ...any) context.Context {
+	if len(kvs) == 0 {
+		return ctx
+	}
+	merged := append(append([]any{}, FromContext(ctx)...), kvs...)
+	return context.WithValue(ctx, kvsContextKey, merged)
+}
+
+// FromContext returns the kvs accumulated on ctx by WithValues, or nil.
+func FromContext(ctx context.Context) []any {
+	if ctx == nil {
+		return nil
+	}
+	kvs, _ := ctx.Value(kvsContextKey).([]any)
+	return kvs
+}
+
+// Logger is a preconfigured, self-contained logger that captures its own
+// sinks and level independently of the package globals, so it can be
+// threaded through a call chain via WithLogger without depending on
+// UseConfig/SetLevel calls made elsewhere.
+type Logger struct {
+	level LogLevel
+
+	debugSinks []sink
+	infoSinks  []sink
+	warnSinks  []sink
+	errorSinks []sink
+	fatalSinks []sink
+}
+
+// NewLogger captures a snapshot of the current package-level sinks and
+// level into a standalone *Logger.
+func NewLogger() *Logger {
+	return &Logger{
+		level:      logLevel,
+		debugSinks: debugSinks,
+		infoSinks:  infoSinks,
+		warnSinks:  warnSinks,
+		errorSinks: errorSinks,
+		fatalSinks: fatalSinks,
+	}
+}
+
+func (l *Logger) sinksFor(level LogLevel) []sink {
+	switch level {
+	case DebugLevel:
+		return l.debugSinks
+	case InfoLevel:
+		return l.infoSinks
+	case WarnLevel:
+		return l.warnSinks
+	case ErrorLevel:
+		return l.errorSinks
+	case FatalLevel:
+		return l.fatalSinks
+	default:
+		return nil
+	}
+}
+
+// SetLevel changes the minimum level l will write at, mirroring the
+// package-level SetLevel but scoped to this Logger alone.
+func (l *Logger) SetLevel(level LogLevel) {
+	l.level = level
+}
+
+func (l *Logger) writeRecord(level LogLevel, caller string, kvs []any) {
+	if level < l.level {
+		return
+	}
+	writeRecordTo(l.sinksFor(level), level, caller, kvs)
+}
+
+// WithLogger returns a context carrying l, so that Info/Warn/Error/Debug
+// calls made with it are routed through l's sinks instead of the package
+// globals.
+func WithLogger(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, l)
+}
+
+func loggerFromContext(ctx context.Context) (*Logger, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	l, ok := ctx.Value(loggerContextKey).(*Logger)
+	return l, ok
+}
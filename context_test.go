@@ -0,0 +1,112 @@
+/*
+ * SPDX-License-Identifier: MIT
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package logs
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestWithValuesMergesInCallOrder(t *testing.T) {
+	ctx := context.Background()
+	ctx = WithValues(ctx, "a", 1)
+	ctx = WithValues(ctx, "b", 2)
+
+	got := FromContext(ctx)
+	want := []any{"a", 1, "b", 2}
+	if len(got) != len(want) {
+		t.Fatalf("FromContext = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("FromContext[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWithValuesNoopOnEmpty(t *testing.T) {
+	ctx := context.Background()
+	if got := WithValues(ctx); got != ctx {
+		t.Error("WithValues with no kvs should return ctx unchanged")
+	}
+}
+
+func TestFromContextNil(t *testing.T) {
+	if got := FromContext(nil); got != nil {
+		t.Errorf("FromContext(nil) = %v, want nil", got)
+	}
+}
+
+func TestLoggerWriteRecordRespectsOwnLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := &Logger{
+		level:     WarnLevel,
+		infoSinks: []sink{{buf, TextEncoder{}}},
+		warnSinks: []sink{{buf, TextEncoder{}}},
+	}
+
+	l.writeRecord(InfoLevel, "file.go:1", []any{"msg", "hidden"})
+	if buf.Len() != 0 {
+		t.Fatalf("expected InfoLevel to be suppressed below WarnLevel, got: %q", buf.String())
+	}
+
+	l.writeRecord(WarnLevel, "file.go:2", []any{"msg", "shown"})
+	if buf.Len() == 0 {
+		t.Fatal("expected WarnLevel record to be written")
+	}
+}
+
+func TestLoggerSetLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := &Logger{
+		level:     ErrorLevel,
+		warnSinks: []sink{{buf, TextEncoder{}}},
+	}
+
+	l.writeRecord(WarnLevel, "file.go:3", []any{"msg", "still hidden"})
+	if buf.Len() != 0 {
+		t.Fatalf("expected WarnLevel to be suppressed below ErrorLevel, got: %q", buf.String())
+	}
+
+	l.SetLevel(WarnLevel)
+	l.writeRecord(WarnLevel, "file.go:4", []any{"msg", "now shown"})
+	if buf.Len() == 0 {
+		t.Fatal("expected WarnLevel record to be written after SetLevel")
+	}
+}
+
+func TestWithLoggerRoundTrip(t *testing.T) {
+	l := NewLogger()
+	ctx := WithLogger(context.Background(), l)
+
+	got, ok := loggerFromContext(ctx)
+	if !ok || got != l {
+		t.Errorf("loggerFromContext = (%v, %v), want (%p, true)", got, ok, l)
+	}
+
+	if _, ok := loggerFromContext(context.Background()); ok {
+		t.Error("loggerFromContext on a plain context should report not found")
+	}
+}
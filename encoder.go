@@ -0,0 +1,206 @@
+/*
+ * SPDX-License-Identifier: MIT
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Encoder renders one log record to w. kvs holds the call-site key/value
+// pairs in call order, including the "msg" and "err" keys injected by
+// makeMsgLogFunc/makeErrLogFunc/makeMsgErrLogFunc.
+type Encoder interface {
+	EncodeRecord(w io.Writer, level LogLevel, ts time.Time, caller string, kvs []any) error
+}
+
+func encoderFor(name string) (Encoder, error) {
+	switch name {
+	case "", "text":
+		return TextEncoder{}, nil
+	case "logfmt":
+		return LogfmtEncoder{}, nil
+	case "json":
+		return JSONEncoder{}, nil
+	default:
+		return nil, fmt.Errorf("logs: unknown encoder %q", name)
+	}
+}
+
+func levelPrefix(level LogLevel) string {
+	switch level {
+	case DebugLevel:
+		return "DEBUG "
+	case InfoLevel:
+		return "INFO  "
+	case WarnLevel:
+		return "WARN  "
+	case ErrorLevel:
+		return "ERROR "
+	case FatalLevel:
+		return "FATAL "
+	default:
+		return "UNKNOWN "
+	}
+}
+
+func levelString(level LogLevel) string {
+	return strings.TrimSpace(levelPrefix(level))
+}
+
+// splitMsgErr pulls the "msg" and "err" keys (if present) out of kvs,
+// returning them alongside the remaining pairs in their original order.
+func splitMsgErr(kvs []any) (msg string, hasMsg bool, errStr string, hasErr bool, rest []any) {
+	rest = make([]any, 0, len(kvs))
+	for i := 0; i+1 < len(kvs); i += 2 {
+		key, _ := kvs[i].(string)
+		switch key {
+		case "msg":
+			msg, hasMsg = fmt.Sprintf("%+v", kvs[i+1]), true
+		case "err":
+			errStr, hasErr = fmt.Sprintf("%+v", kvs[i+1]), true
+		default:
+			rest = append(rest, kvs[i], kvs[i+1])
+		}
+	}
+	return
+}
+
+// TextEncoder reproduces the original hard-coded " %s=%+v" rendering,
+// kept for back-compat with existing log scrapers.
+type TextEncoder struct{}
+
+func (TextEncoder) EncodeRecord(w io.Writer, level LogLevel, ts time.Time, caller string, kvs []any) error {
+	sb := &strings.Builder{}
+	for i := 0; i+1 < len(kvs); i += 2 {
+		_, _ = fmt.Fprintf(sb, " %s=%+v", kvs[i], kvs[i+1])
+	}
+	_, err := fmt.Fprintf(w, "%s%s %s:%s\n", levelPrefix(level), ts.Format("15:04:05"), caller, sb.String())
+	return err
+}
+
+// LogfmtEncoder renders a valid logfmt line: level, ts and caller fields,
+// followed by msg and err (if present), followed by the remaining kvs in
+// call order. Keys and values containing whitespace, `"`, `=` or control
+// characters are quoted and escaped.
+type LogfmtEncoder struct{}
+
+func (LogfmtEncoder) EncodeRecord(w io.Writer, level LogLevel, ts time.Time, caller string, kvs []any) error {
+	sb := &strings.Builder{}
+	writeLogfmtPair(sb, "level", levelString(level))
+	writeLogfmtPair(sb, "ts", ts.Format(time.RFC3339Nano))
+	writeLogfmtPair(sb, "caller", caller)
+
+	msg, hasMsg, errStr, hasErr, rest := splitMsgErr(kvs)
+	if hasMsg {
+		writeLogfmtPair(sb, "msg", msg)
+	}
+	if hasErr {
+		writeLogfmtPair(sb, "err", errStr)
+	}
+	for i := 0; i+1 < len(rest); i += 2 {
+		writeLogfmtPair(sb, fmt.Sprintf("%+v", rest[i]), fmt.Sprintf("%+v", rest[i+1]))
+	}
+	sb.WriteByte('\n')
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeLogfmtPair(sb *strings.Builder, key, value string) {
+	if sb.Len() > 0 {
+		sb.WriteByte(' ')
+	}
+	sb.WriteString(quoteLogfmtValue(key))
+	sb.WriteByte('=')
+	sb.WriteString(quoteLogfmtValue(value))
+}
+
+func quoteLogfmtValue(s string) string {
+	needsQuote := false
+	for _, r := range s {
+		if unicode.IsSpace(r) || unicode.IsControl(r) || r == '"' || r == '=' {
+			needsQuote = true
+			break
+		}
+	}
+	if s == "" {
+		needsQuote = true
+	}
+	if !needsQuote {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+// JSONEncoder renders one JSON object per line with level, ts, caller, msg
+// and err promoted to top-level fields, plus every other kv as its own key.
+type JSONEncoder struct{}
+
+func (JSONEncoder) EncodeRecord(w io.Writer, level LogLevel, ts time.Time, caller string, kvs []any) error {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	writeJSONStringField(buf, true, "level", levelString(level))
+	writeJSONStringField(buf, false, "ts", ts.Format(time.RFC3339Nano))
+	writeJSONStringField(buf, false, "caller", caller)
+
+	msg, hasMsg, errStr, hasErr, rest := splitMsgErr(kvs)
+	if hasMsg {
+		writeJSONStringField(buf, false, "msg", msg)
+	}
+	if hasErr {
+		writeJSONStringField(buf, false, "err", errStr)
+	}
+	for i := 0; i+1 < len(rest); i += 2 {
+		key := fmt.Sprintf("%v", rest[i])
+		valBytes, err := json.Marshal(rest[i+1])
+		if err != nil {
+			valBytes, _ = json.Marshal(fmt.Sprintf("%+v", rest[i+1]))
+		}
+		buf.WriteByte(',')
+		keyBytes, _ := json.Marshal(key)
+		buf.Write(keyBytes)
+		buf.WriteByte(':')
+		buf.Write(valBytes)
+	}
+	buf.WriteString("}\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeJSONStringField(buf *bytes.Buffer, first bool, key, value string) {
+	if !first {
+		buf.WriteByte(',')
+	}
+	keyBytes, _ := json.Marshal(key)
+	valBytes, _ := json.Marshal(value)
+	buf.Write(keyBytes)
+	buf.WriteByte(':')
+	buf.Write(valBytes)
+}
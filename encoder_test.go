@@ -0,0 +1,111 @@
+/*
+ * SPDX-License-Identifier: MIT
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQuoteLogfmtValue(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{"", `""`},
+		{"has space", `"has space"`},
+		{`has"quote`, `"has\"quote"`},
+		{"has=equals", `"has=equals"`},
+	}
+	for _, c := range cases {
+		if got := quoteLogfmtValue(c.in); got != c.want {
+			t.Errorf("quoteLogfmtValue(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestLogfmtEncoder_QuotesKeysAndValues(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := LogfmtEncoder{}.EncodeRecord(buf, InfoLevel, ts, "file.go:1", []any{"msg", "hi", "a=b c", "val ue"})
+	if err != nil {
+		t.Fatalf("EncodeRecord: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "level=INFO ts=") {
+		t.Errorf("expected level/ts fields first, got: %q", out)
+	}
+	if !strings.Contains(out, "msg=hi") {
+		t.Errorf("expected msg field, got: %q", out)
+	}
+	if !strings.Contains(out, `"a=b c"="val ue"`) {
+		t.Errorf("expected key and value both quoted, got: %q", out)
+	}
+}
+
+func TestJSONEncoder_EncodeRecord(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := JSONEncoder{}.EncodeRecord(buf, ErrorLevel, ts, "file.go:2", []any{"msg", "boom", "err", "disk full", "retries", 3})
+	if err != nil {
+		t.Fatalf("EncodeRecord: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON produced: %v\n%s", err, buf.String())
+	}
+	if got["level"] != "ERROR" {
+		t.Errorf("level = %v, want ERROR", got["level"])
+	}
+	if got["caller"] != "file.go:2" {
+		t.Errorf("caller = %v, want file.go:2", got["caller"])
+	}
+	if got["msg"] != "boom" {
+		t.Errorf("msg = %v, want boom", got["msg"])
+	}
+	if got["err"] != "disk full" {
+		t.Errorf("err = %v, want %q", got["err"], "disk full")
+	}
+	if got["retries"] != float64(3) {
+		t.Errorf("retries = %v, want 3", got["retries"])
+	}
+}
+
+func TestTextEncoder_EncodeRecord(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := TextEncoder{}.EncodeRecord(buf, DebugLevel, ts, "file.go:3", []any{"k", "v"})
+	if err != nil {
+		t.Fatalf("EncodeRecord: %v", err)
+	}
+	want := "DEBUG 03:04:05 file.go:3: k=v\n"
+	if buf.String() != want {
+		t.Errorf("TextEncoder output = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+ * SPDX-License-Identifier: MIT
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package logs
+
+import (
+	"context"
+	"io"
+	"os"
+	"runtime"
+)
+
+// ExitFunc is called by Fatal/FatalM/FatalE/FatalR after the fatal record
+// has been written and every open writer flushed. Tests can swap it out
+// (e.g. for a function that panics) to observe a fatal call without
+// killing the process.
+var ExitFunc = os.Exit
+
+type syncer interface {
+	Sync() error
+}
+
+// flushAllWriters calls Sync on every distinct writer currently backing a
+// package-level sink, plus whichever extra sink groups the caller passes
+// (typically a contextual *Logger's own sinks), best-effort.
+func flushAllWriters(extra ...[]sink) {
+	seen := map[io.Writer]bool{}
+	groups := [][]sink{debugSinks, infoSinks, warnSinks, errorSinks, fatalSinks}
+	groups = append(groups, extra...)
+	for _, sinks := range groups {
+		for _, s := range sinks {
+			if seen[s.writer] {
+				continue
+			}
+			seen[s.writer] = true
+			if f, ok := s.writer.(syncer); ok {
+				_ = f.Sync()
+			}
+		}
+	}
+}
+
+// loggerSinkGroups returns l's own sink slices, so flushAllWriters can flush
+// a contextual Logger's writers in addition to the package globals.
+func loggerSinkGroups(l *Logger) [][]sink {
+	return [][]sink{l.debugSinks, l.infoSinks, l.warnSinks, l.errorSinks, l.fatalSinks}
+}
+
+func captureStack() string {
+	buf := make([]byte, 1<<16)
+	n := runtime.Stack(buf, true)
+	return string(buf[:n])
+}
+
+func fatalKvLog(ctx context.Context, kvs []any) {
+	full := append(append([]any{}, FromContext(ctx)...), kvs...)
+	full = append(full, "stack", captureStack())
+
+	caller := callerString(3)
+	if l, ok := loggerFromContext(ctx); ok {
+		l.writeRecord(FatalLevel, caller, full)
+		flushAllWriters(loggerSinkGroups(l)...)
+	} else {
+		writeRecord(FatalLevel, caller, full)
+		flushAllWriters()
+	}
+
+	ExitFunc(255)
+}
+
+func FatalR(ctx context.Context, kvs ...any) {
+	fatalKvLog(ctx, kvs)
+}
+
+func FatalM(ctx context.Context, msg string, kvs ...any) {
+	fatalKvLog(ctx, append([]any{"msg", msg}, kvs...))
+}
+
+func FatalE(ctx context.Context, err error, kvs ...any) {
+	fatalKvLog(ctx, append(kvs, "err", err.Error()))
+}
+
+func Fatal(ctx context.Context, msg string, err error, kvs ...any) {
+	kvs = append([]any{"msg", msg}, kvs...)
+	kvs = append(kvs, "err", err.Error())
+	fatalKvLog(ctx, kvs)
+}
+
+// Panic logs at FatalLevel like Fatal, but re-panics with err instead of
+// calling ExitFunc, for callers that want their own recover() to run.
+func Panic(ctx context.Context, msg string, err error, kvs ...any) {
+	full := append([]any{"msg", msg}, kvs...)
+	full = append(full, "err", err.Error())
+	full = append(append([]any{}, FromContext(ctx)...), full...)
+	full = append(full, "stack", captureStack())
+
+	caller := callerString(2)
+	if l, ok := loggerFromContext(ctx); ok {
+		l.writeRecord(FatalLevel, caller, full)
+		flushAllWriters(loggerSinkGroups(l)...)
+	} else {
+		writeRecord(FatalLevel, caller, full)
+		flushAllWriters()
+	}
+
+	panic(err)
+}
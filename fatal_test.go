@@ -0,0 +1,124 @@
+/*
+ * SPDX-License-Identifier: MIT
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package logs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// syncBuffer is an io.Writer that also satisfies syncer, so flushAllWriters
+// picks it up.
+type syncBuffer struct {
+	bytes.Buffer
+	synced bool
+}
+
+func (s *syncBuffer) Sync() error {
+	s.synced = true
+	return nil
+}
+
+func TestCaptureStackContainsCaller(t *testing.T) {
+	stack := captureStack()
+	if !strings.Contains(stack, "TestCaptureStackContainsCaller") {
+		t.Errorf("captureStack() missing this test's frame:\n%s", stack)
+	}
+}
+
+func TestFlushAllWritersSyncsGlobalsAndExtras(t *testing.T) {
+	globalBuf := &syncBuffer{}
+	extraBuf := &syncBuffer{}
+
+	oldFatal := fatalSinks
+	fatalSinks = []sink{{globalBuf, TextEncoder{}}}
+	t.Cleanup(func() { fatalSinks = oldFatal })
+
+	flushAllWriters([]sink{{extraBuf, TextEncoder{}}})
+
+	if !globalBuf.synced {
+		t.Error("expected the global fatalSinks writer to be synced")
+	}
+	if !extraBuf.synced {
+		t.Error("expected the extra (contextual Logger) sink writer to be synced")
+	}
+}
+
+func TestFatalFlushesContextualLoggerSinks(t *testing.T) {
+	buf := &syncBuffer{}
+	l := &Logger{
+		level:      AllLevels,
+		fatalSinks: []sink{{buf, TextEncoder{}}},
+	}
+	ctx := WithLogger(context.Background(), l)
+
+	oldExit := ExitFunc
+	var exited bool
+	var exitCode int
+	ExitFunc = func(code int) {
+		exited = true
+		exitCode = code
+	}
+	t.Cleanup(func() { ExitFunc = oldExit })
+
+	FatalM(ctx, "boom")
+
+	if !exited || exitCode != 255 {
+		t.Fatalf("exited = %v, code = %d, want (true, 255)", exited, exitCode)
+	}
+	if buf.Len() == 0 {
+		t.Fatal("expected the fatal record to be written to the contextual Logger's sink")
+	}
+	if !buf.synced {
+		t.Error("expected the contextual Logger's sink to be synced before exit")
+	}
+}
+
+func TestPanicRePanicsWithErrAndFlushes(t *testing.T) {
+	buf := &syncBuffer{}
+	l := &Logger{
+		level:      AllLevels,
+		fatalSinks: []sink{{buf, TextEncoder{}}},
+	}
+	ctx := WithLogger(context.Background(), l)
+	wantErr := errors.New("kaboom")
+
+	defer func() {
+		r := recover()
+		if r != error(wantErr) {
+			t.Fatalf("recovered %v, want %v", r, wantErr)
+		}
+		if buf.Len() == 0 {
+			t.Error("expected the fatal record to be written before panicking")
+		}
+		if !buf.synced {
+			t.Error("expected the contextual Logger's sink to be synced before panicking")
+		}
+	}()
+
+	Panic(ctx, "dying", wantErr)
+}
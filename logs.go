@@ -27,9 +27,9 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"log"
 	"os"
-	"strings"
+	"runtime"
+	"time"
 )
 
 type LogLevel int8
@@ -40,18 +40,29 @@ const (
 	InfoLevel
 	WarnLevel
 	ErrorLevel
+	FatalLevel
 	NoLevels
 )
 
+// sink pairs a destination writer with the encoder used to render records
+// written to it.
+type sink struct {
+	writer  io.Writer
+	encoder Encoder
+}
+
 var (
 	logLevel = InfoLevel
-	errorLog = log.New(os.Stdout, "ERROR ", log.Ltime|log.Llongfile)
-	warnLog  = log.New(os.Stdout, "WARN  ", log.Ltime|log.Llongfile)
-	infoLog  = log.New(os.Stdout, "INFO  ", log.Ltime|log.Llongfile)
-	debugLog = log.New(os.Stdout, "DEBUG ", log.Ltime|log.Llongfile)
+
+	fatalSinks = []sink{{os.Stdout, TextEncoder{}}}
+	errorSinks = []sink{{os.Stdout, TextEncoder{}}}
+	warnSinks  = []sink{{os.Stdout, TextEncoder{}}}
+	infoSinks  = []sink{{os.Stdout, TextEncoder{}}}
+	debugSinks = []sink{{os.Stdout, TextEncoder{}}}
 )
 
-func resetLoggerForLevels(from, to LogLevel, logger io.Writer) {
+func resetSinksForLevels(from, to LogLevel, writer io.Writer) {
+	sinks := []sink{{writer, TextEncoder{}}}
 	switch from {
 	case AllLevels:
 		if to == DebugLevel {
@@ -59,34 +70,40 @@ func resetLoggerForLevels(from, to LogLevel, logger io.Writer) {
 		}
 		fallthrough
 	case DebugLevel:
-		debugLog = log.New(logger, "DEBUG ", log.Ltime|log.Llongfile)
+		debugSinks = sinks
 		if to == InfoLevel {
 			break
 		}
 		fallthrough
 	case InfoLevel:
-		infoLog = log.New(logger, "INFO  ", log.Ltime|log.Llongfile)
+		infoSinks = sinks
 		if to == WarnLevel {
 			break
 		}
 		fallthrough
 	case WarnLevel:
-		warnLog = log.New(logger, "WARN  ", log.Ltime|log.Llongfile)
+		warnSinks = sinks
 		if to == ErrorLevel {
 			break
 		}
 		fallthrough
 	case ErrorLevel:
-		errorLog = log.New(logger, "ERROR ", log.Ltime|log.Llongfile)
+		errorSinks = sinks
+		if to == FatalLevel {
+			break
+		}
+		fallthrough
+	case FatalLevel:
+		fatalSinks = sinks
 	}
 }
 
 func enableLevels(from, to LogLevel) {
-	resetLoggerForLevels(from, to, os.Stdout)
+	resetSinksForLevels(from, to, os.Stdout)
 }
 
 func disableLevels(from, to LogLevel) {
-	resetLoggerForLevels(from, to, io.Discard)
+	resetSinksForLevels(from, to, io.Discard)
 }
 
 func SetLevel(level LogLevel) {
@@ -100,19 +117,51 @@ func SetLevel(level LogLevel) {
 	logLevel = level
 }
 
-func format(kvs ...any) string {
-	sb := &strings.Builder{}
-	//_, _ = fmt.Fprintf(sb, "Module=%s Function=%s Message=%s", module, function, message)
-	for i := 0; i < len(kvs)-1; i += 2 {
-		_, _ = fmt.Fprintf(sb, " %s=%+v", kvs[i], kvs[i+1])
+func sinksFor(level LogLevel) []sink {
+	switch level {
+	case DebugLevel:
+		return debugSinks
+	case InfoLevel:
+		return infoSinks
+	case WarnLevel:
+		return warnSinks
+	case ErrorLevel:
+		return errorSinks
+	case FatalLevel:
+		return fatalSinks
+	default:
+		return nil
 	}
-	return sb.String()
 }
 
-func makeKvLogFunc(level LogLevel, pLogger **log.Logger) func(ctx context.Context, kvs ...any) {
+func callerString(skip int) string {
+	_, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return "???:0"
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+func writeRecordTo(sinks []sink, level LogLevel, caller string, kvs []any) {
+	ts := time.Now()
+	for _, s := range sinks {
+		_ = s.encoder.EncodeRecord(s.writer, level, ts, caller, kvs)
+	}
+}
+
+func writeRecord(level LogLevel, caller string, kvs []any) {
+	writeRecordTo(sinksFor(level), level, caller, kvs)
+}
+
+func makeKvLogFunc(level LogLevel) func(ctx context.Context, kvs ...any) {
 	return func(ctx context.Context, kvs ...any) {
-		str := format(kvs...)
-		_ = (*pLogger).Output(3, str)
+		full := append(append([]any{}, FromContext(ctx)...), kvs...)
+		caller := callerString(3)
+		if l, ok := loggerFromContext(ctx); ok {
+			l.writeRecord(level, caller, full)
+			return
+		}
+		writeRecord(level, caller, full)
 	}
 }
 
@@ -146,10 +195,10 @@ func makeMsgErrLogFunc(f func(context.Context, ...any)) func(ctx context.Context
 
 //goland:noinspection GoUnusedGlobalVariable
 var (
-	errorLogger = makeKvLogFunc(ErrorLevel, &errorLog)
-	warnLogger  = makeKvLogFunc(WarnLevel, &warnLog)
-	infoLogger  = makeKvLogFunc(InfoLevel, &infoLog)
-	debugLogger = makeKvLogFunc(DebugLevel, &debugLog)
+	errorLogger = makeKvLogFunc(ErrorLevel)
+	warnLogger  = makeKvLogFunc(WarnLevel)
+	infoLogger  = makeKvLogFunc(InfoLevel)
+	debugLogger = makeKvLogFunc(DebugLevel)
 
 	ErrorR = makeBasicLogFunc(errorLogger)
 	WarnR  = makeBasicLogFunc(warnLogger)
@@ -0,0 +1,328 @@
+/*
+ * SPDX-License-Identifier: MIT
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package logs
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotatingFile is an io.Writer/io.Closer wrapping a log file that rotates
+// itself by size and/or time, pruning and compressing old segments.
+// The same *rotatingFile is shared by every sink configured with the same
+// filename, so Write is safe to call concurrently.
+type rotatingFile struct {
+	mu sync.Mutex
+
+	filename       string
+	perm           os.FileMode
+	maxSizeBytes   int64
+	maxBackups     int
+	maxAgeDays     int
+	rotateInterval time.Duration
+	compressAfter  time.Duration
+	symlink        bool
+
+	file       *os.File
+	activePath string
+	size       int64
+	openedAt   time.Time
+	seq        uint64
+}
+
+type rotateConfig struct {
+	Perm           os.FileMode
+	MaxSizeBytes   int64
+	MaxBackups     int
+	MaxAgeDays     int
+	RotateInterval time.Duration
+	CompressAfter  time.Duration
+	Symlink        bool
+}
+
+var (
+	rotatingFilesMu sync.Mutex
+	rotatingFiles   = map[string]*rotatingFile{}
+)
+
+// getRotatingFile returns the rotatingFile for filename, creating and
+// opening it on first use and reusing it for every later sink that points
+// at the same file.
+func getRotatingFile(filename string, cfg rotateConfig) (*rotatingFile, error) {
+	rotatingFilesMu.Lock()
+	defer rotatingFilesMu.Unlock()
+
+	key := filename
+	if abs, err := filepath.Abs(filename); err == nil {
+		key = abs
+	}
+	if rf, ok := rotatingFiles[key]; ok {
+		rf.updateConfigLocked(cfg)
+		return rf, nil
+	}
+
+	rf := &rotatingFile{
+		filename:       filename,
+		perm:           cfg.Perm,
+		maxSizeBytes:   cfg.MaxSizeBytes,
+		maxBackups:     cfg.MaxBackups,
+		maxAgeDays:     cfg.MaxAgeDays,
+		rotateInterval: cfg.RotateInterval,
+		compressAfter:  cfg.CompressAfter,
+		symlink:        cfg.Symlink,
+	}
+	if err := rf.openLocked(); err != nil {
+		return nil, err
+	}
+	rotatingFiles[key] = rf
+	return rf, nil
+}
+
+func parseRotateInterval(spec string) (time.Duration, error) {
+	switch strings.ToLower(strings.TrimSpace(spec)) {
+	case "":
+		return 0, nil
+	case "hourly":
+		return time.Hour, nil
+	case "daily":
+		return 24 * time.Hour, nil
+	default:
+		return 0, fmt.Errorf("logs: invalid rotate_interval %q", spec)
+	}
+}
+
+// segmentName returns a new timestamped segment path for r.filename, e.g.
+// "<name>.20060102-150405.<pid>.<seq>". seq disambiguates rotations that
+// land within the same wall-clock second, which the timestamp alone can't.
+func (r *rotatingFile) segmentName() string {
+	r.seq++
+	return fmt.Sprintf("%s.%s.%d.%d", r.filename, time.Now().Format("20060102-150405"), os.Getpid(), r.seq)
+}
+
+func (r *rotatingFile) openLocked() error {
+	path := r.filename
+	if r.symlink {
+		// r.filename is reserved for the symlink itself; the file actually
+		// being written is always a fresh timestamped segment, so the
+		// symlink tracks the newest segment instead of being
+		// self-referential.
+		path = r.segmentName()
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, r.perm)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	r.file = file
+	r.activePath = path
+	r.size = info.Size()
+	r.openedAt = time.Now()
+	if r.symlink {
+		r.updateSymlinkLocked(path)
+	}
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.shouldRotateLocked(len(p)) {
+		if err := r.rotateLocked(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// updateConfigLocked refreshes the rotation knobs on an already-open file
+// from a later UseConfig call. Perm and Symlink are left alone: neither
+// makes sense to change retroactively for a file that's already open under
+// the old permissions, or already committed to symlink-vs-direct naming.
+func (r *rotatingFile) updateConfigLocked(cfg rotateConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maxSizeBytes = cfg.MaxSizeBytes
+	r.maxBackups = cfg.MaxBackups
+	r.maxAgeDays = cfg.MaxAgeDays
+	r.rotateInterval = cfg.RotateInterval
+	r.compressAfter = cfg.CompressAfter
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// Sync flushes the current segment to disk, used by Fatal to make sure
+// every open file writer has the fatal record on it before exiting.
+func (r *rotatingFile) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Sync()
+}
+
+func (r *rotatingFile) shouldRotateLocked(next int) bool {
+	if r.maxSizeBytes > 0 && r.size+int64(next) > r.maxSizeBytes {
+		return true
+	}
+	if r.rotateInterval > 0 && !time.Now().Truncate(r.rotateInterval).Equal(r.openedAt.Truncate(r.rotateInterval)) {
+		return true
+	}
+	return false
+}
+
+func (r *rotatingFile) rotateLocked() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+
+	if !r.symlink {
+		// The active file is r.filename itself; rename it out of the way so
+		// a fresh r.filename can be opened for the next segment.
+		if err := os.Rename(r.filename, r.segmentName()); err != nil {
+			return err
+		}
+	}
+	// In symlink mode the active file is already a timestamped segment, so
+	// it's left in place and openLocked starts a new one.
+
+	if err := r.openLocked(); err != nil {
+		return err
+	}
+
+	r.compressAndPruneLocked()
+	return nil
+}
+
+// updateSymlinkLocked points the stable r.filename symlink at target, the
+// newest segment, like glog does.
+func (r *rotatingFile) updateSymlinkLocked(target string) {
+	_ = os.Remove(r.filename)
+	_ = os.Symlink(filepath.Base(target), r.filename)
+}
+
+// segment describes one rotated backup of filename.
+type segment struct {
+	path    string
+	modTime time.Time
+}
+
+func (r *rotatingFile) backupsLocked() []segment {
+	paths, err := filepath.Glob(r.filename + ".*")
+	if err != nil {
+		return nil
+	}
+	segments := make([]segment, 0, len(paths))
+	for _, p := range paths {
+		if p == r.activePath {
+			continue
+		}
+		info, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, segment{path: p, modTime: info.ModTime()})
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].modTime.After(segments[j].modTime) })
+	return segments
+}
+
+func (r *rotatingFile) compressAndPruneLocked() {
+	segments := r.backupsLocked()
+
+	if r.compressAfter > 0 {
+		cutoff := time.Now().Add(-r.compressAfter)
+		for i, s := range segments {
+			if strings.HasSuffix(s.path, ".gz") || s.modTime.After(cutoff) {
+				continue
+			}
+			if gzPath, err := gzipFile(s.path); err == nil {
+				segments[i] = segment{path: gzPath, modTime: s.modTime}
+			}
+		}
+	}
+
+	var toDelete []segment
+	if r.maxBackups > 0 && len(segments) > r.maxBackups {
+		toDelete = append(toDelete, segments[r.maxBackups:]...)
+		segments = segments[:r.maxBackups]
+	}
+	if r.maxAgeDays > 0 {
+		cutoff := time.Now().AddDate(0, 0, -r.maxAgeDays)
+		kept := segments[:0]
+		for _, s := range segments {
+			if s.modTime.Before(cutoff) {
+				toDelete = append(toDelete, s)
+			} else {
+				kept = append(kept, s)
+			}
+		}
+		segments = kept
+	}
+	for _, s := range toDelete {
+		_ = os.Remove(s.path)
+	}
+}
+
+func gzipFile(path string) (string, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dstPath := path + ".gz"
+	dst, err := os.OpenFile(dstPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", err
+	}
+	defer dst.Close()
+
+	gw := gzip.NewWriter(dst)
+	if _, err := io.Copy(gw, src); err != nil {
+		_ = gw.Close()
+		return "", err
+	}
+	if err := gw.Close(); err != nil {
+		return "", err
+	}
+	_ = os.Remove(path)
+	return dstPath, nil
+}
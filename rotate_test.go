@@ -0,0 +1,272 @@
+/*
+ * SPDX-License-Identifier: MIT
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package logs
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseRotateInterval(t *testing.T) {
+	cases := []struct {
+		spec    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"hourly", time.Hour, false},
+		{"Daily", 24 * time.Hour, false},
+		{"weekly", 0, true},
+	}
+	for _, c := range cases {
+		got, err := parseRotateInterval(c.spec)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("parseRotateInterval(%q): expected error, got nil", c.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseRotateInterval(%q): unexpected error: %v", c.spec, err)
+		}
+		if got != c.want {
+			t.Errorf("parseRotateInterval(%q) = %v, want %v", c.spec, got, c.want)
+		}
+	}
+}
+
+func TestRotatingFileSizeRotation(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "test.log")
+
+	rf, err := getRotatingFile(name, rotateConfig{Perm: 0o644, MaxSizeBytes: 10})
+	if err != nil {
+		t.Fatalf("getRotatingFile: %v", err)
+	}
+	defer rf.Close()
+
+	if _, err := rf.Write([]byte("12345")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := rf.Write([]byte("1234567890")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	backups, _ := filepath.Glob(name + ".*")
+	if len(backups) != 1 {
+		t.Fatalf("expected 1 rotated backup, got %d: %v", len(backups), backups)
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("read current segment: %v", err)
+	}
+	if string(data) != "1234567890" {
+		t.Errorf("current segment = %q, want %q", data, "1234567890")
+	}
+}
+
+func TestRotatingFileMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	rf := &rotatingFile{filename: name, maxBackups: 2}
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		backup := fmt.Sprintf("%s.%d", name, i)
+		if err := os.WriteFile(backup, []byte("x"), 0o644); err != nil {
+			t.Fatalf("write backup: %v", err)
+		}
+		mtime := now.Add(-time.Duration(5-i) * time.Hour)
+		if err := os.Chtimes(backup, mtime, mtime); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+	}
+
+	rf.compressAndPruneLocked()
+
+	remaining, _ := filepath.Glob(name + ".*")
+	if len(remaining) != 2 {
+		t.Fatalf("expected 2 backups after pruning to maxBackups, got %d: %v", len(remaining), remaining)
+	}
+}
+
+func TestGetRotatingFileRefreshesConfigOnReRegistration(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+
+	rf, err := getRotatingFile(name, rotateConfig{Perm: 0o644, MaxSizeBytes: 1024, MaxBackups: 1})
+	if err != nil {
+		t.Fatalf("getRotatingFile: %v", err)
+	}
+	defer rf.Close()
+
+	rf2, err := getRotatingFile(name, rotateConfig{Perm: 0o644, MaxSizeBytes: 64, MaxBackups: 5})
+	if err != nil {
+		t.Fatalf("getRotatingFile (second call): %v", err)
+	}
+	if rf2 != rf {
+		t.Fatalf("expected the same *rotatingFile to be reused for %q", name)
+	}
+	if rf.maxSizeBytes != 64 {
+		t.Errorf("maxSizeBytes = %d, want 64 (second call's config should win)", rf.maxSizeBytes)
+	}
+	if rf.maxBackups != 5 {
+		t.Errorf("maxBackups = %d, want 5 (second call's config should win)", rf.maxBackups)
+	}
+}
+
+func TestRotatingFileMaxAgeDays(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	rf := &rotatingFile{filename: name, maxAgeDays: 1}
+
+	now := time.Now()
+	fresh := name + ".fresh"
+	stale := name + ".stale"
+	if err := os.WriteFile(fresh, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write fresh: %v", err)
+	}
+	if err := os.WriteFile(stale, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write stale: %v", err)
+	}
+	if err := os.Chtimes(fresh, now, now); err != nil {
+		t.Fatalf("chtimes fresh: %v", err)
+	}
+	staleTime := now.AddDate(0, 0, -2)
+	if err := os.Chtimes(stale, staleTime, staleTime); err != nil {
+		t.Fatalf("chtimes stale: %v", err)
+	}
+
+	rf.compressAndPruneLocked()
+
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("fresh backup should remain: %v", err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale backup should be pruned by max_age_days, stat err = %v", err)
+	}
+}
+
+func TestRotatingFileCompress(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	rf := &rotatingFile{filename: name, compressAfter: time.Hour}
+
+	seg := name + ".seg"
+	if err := os.WriteFile(seg, []byte("hello"), 0o644); err != nil {
+		t.Fatalf("write segment: %v", err)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(seg, old, old); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	rf.compressAndPruneLocked()
+
+	if _, err := os.Stat(seg); !os.IsNotExist(err) {
+		t.Errorf("original segment should be removed after compression, stat err = %v", err)
+	}
+
+	gzPath := seg + ".gz"
+	f, err := os.Open(gzPath)
+	if err != nil {
+		t.Fatalf("open compressed segment: %v", err)
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	data, err := io.ReadAll(gr)
+	if err != nil {
+		t.Fatalf("read gzip content: %v", err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("decompressed content = %q, want %q", data, "hello")
+	}
+}
+
+func TestRotatingFileSymlink(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	rf := &rotatingFile{filename: name, symlink: true}
+
+	target := name + ".segment"
+	if err := os.WriteFile(target, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write target: %v", err)
+	}
+
+	rf.updateSymlinkLocked(target)
+
+	resolved, err := os.Readlink(name)
+	if err != nil {
+		t.Fatalf("readlink: %v", err)
+	}
+	if resolved != filepath.Base(target) {
+		t.Errorf("symlink target = %q, want %q", resolved, filepath.Base(target))
+	}
+}
+
+func TestRotatingFileSymlinkTracksRotatedSegments(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+
+	rf, err := getRotatingFile(name, rotateConfig{Perm: 0o644, MaxSizeBytes: 5, Symlink: true})
+	if err != nil {
+		t.Fatalf("getRotatingFile: %v", err)
+	}
+	defer rf.Close()
+
+	if _, err := rf.Write([]byte("1234")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	firstSegment := rf.activePath
+
+	if _, err := rf.Write([]byte("567890")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	secondSegment := rf.activePath
+
+	if secondSegment == firstSegment {
+		t.Fatalf("expected rotation to open a new segment, still on %q", firstSegment)
+	}
+	if secondSegment == name {
+		t.Fatalf("active segment should never be named %q itself", name)
+	}
+
+	resolved, err := os.Readlink(name)
+	if err != nil {
+		t.Fatalf("readlink: %v", err)
+	}
+	if resolved != filepath.Base(secondSegment) {
+		t.Errorf("symlink = %q, want it to resolve to the newest segment %q", resolved, filepath.Base(secondSegment))
+	}
+}
@@ -0,0 +1,177 @@
+/*
+ * SPDX-License-Identifier: MIT
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package logs
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Verbose is the result of V(level): logging through it is a no-op unless
+// the level was at or below the configured verbosity for the call site.
+type Verbose bool
+
+var (
+	verbosity int32
+
+	vmoduleMu         sync.RWMutex
+	vmodulePatterns   []vmodulePattern
+	vmoduleGeneration uint64
+
+	vCache sync.Map // uintptr(pc) -> vCacheEntry
+)
+
+type vmodulePattern struct {
+	pattern string
+	level   int32
+}
+
+type vCacheEntry struct {
+	generation uint64
+	level      int32
+}
+
+// SetVerbosity sets the global verbosity threshold used by V when a call
+// site's file does not match any SetVModule pattern.
+func SetVerbosity(level int32) {
+	atomic.StoreInt32(&verbosity, level)
+	atomic.AddUint64(&vmoduleGeneration, 1)
+}
+
+// SetVModule sets per-file verbosity overrides from a comma-separated list
+// of pattern=level pairs, e.g. "gopls*=2,foo/bar.go=3,baz=1". pattern is
+// matched against both the call site's base filename and its full path
+// using filepath.Match glob syntax.
+func SetVModule(spec string) error {
+	patterns, err := parseVModule(spec)
+	if err != nil {
+		return err
+	}
+	vmoduleMu.Lock()
+	vmodulePatterns = patterns
+	vmoduleMu.Unlock()
+	atomic.AddUint64(&vmoduleGeneration, 1)
+	return nil
+}
+
+func parseVModule(spec string) ([]vmodulePattern, error) {
+	var patterns []vmodulePattern
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("logs: invalid vmodule pattern %q", part)
+		}
+		level, err := strconv.ParseInt(kv[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("logs: invalid vmodule level in %q: %w", part, err)
+		}
+		patterns = append(patterns, vmodulePattern{pattern: kv[0], level: int32(level)})
+	}
+	return patterns, nil
+}
+
+// vmoduleLevel returns the level of the last vmodule pattern (in spec order)
+// that matches file, if any.
+func vmoduleLevel(file string) (int32, bool) {
+	vmoduleMu.RLock()
+	defer vmoduleMu.RUnlock()
+
+	base := filepath.Base(file)
+	level, found := int32(0), false
+	for _, p := range vmodulePatterns {
+		if matched, _ := filepath.Match(p.pattern, base); matched {
+			level, found = p.level, true
+			continue
+		}
+		if matched, _ := filepath.Match(p.pattern, file); matched {
+			level, found = p.level, true
+		}
+	}
+	return level, found
+}
+
+func effectiveVLevel(pc uintptr) int32 {
+	gen := atomic.LoadUint64(&vmoduleGeneration)
+	if cached, ok := vCache.Load(pc); ok {
+		entry := cached.(vCacheEntry)
+		if entry.generation == gen {
+			return entry.level
+		}
+	}
+
+	level := atomic.LoadInt32(&verbosity)
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		file, _ := fn.FileLine(pc)
+		if l, found := vmoduleLevel(file); found {
+			level = l
+		}
+	}
+	vCache.Store(pc, vCacheEntry{generation: gen, level: level})
+	return level
+}
+
+// V reports whether logging at level is enabled for the caller's call site,
+// honouring both the global verbosity and any SetVModule override for the
+// caller's file.
+func V(level int32) Verbose {
+	pc, _, _, ok := runtime.Caller(1)
+	if !ok {
+		return Verbose(atomic.LoadInt32(&verbosity) >= level)
+	}
+	return Verbose(effectiveVLevel(pc) >= level)
+}
+
+func (v Verbose) Info(ctx context.Context, msg string, err error, kvs ...any) {
+	if v {
+		Info(ctx, msg, err, kvs...)
+	}
+}
+
+func (v Verbose) InfoM(ctx context.Context, msg string, kvs ...any) {
+	if v {
+		InfoM(ctx, msg, kvs...)
+	}
+}
+
+func (v Verbose) InfoR(ctx context.Context, kvs ...any) {
+	if v {
+		InfoR(ctx, kvs...)
+	}
+}
+
+func (v Verbose) Infof(format string, args ...any) {
+	if v {
+		writeRecord(InfoLevel, callerString(2), []any{"msg", fmt.Sprintf(format, args...)})
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ * SPDX-License-Identifier: MIT
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy of
+ * this software and associated documentation files (the "Software"), to deal in
+ * the Software without restriction, including without limitation the rights to
+ * use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+ * the Software, and to permit persons to whom the Software is furnished to do so,
+ * subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+ * FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+ * COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+ * IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+ * CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+package logs
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestParseVModule(t *testing.T) {
+	patterns, err := parseVModule("foo*=2, bar.go=3")
+	if err != nil {
+		t.Fatalf("parseVModule: %v", err)
+	}
+	want := []vmodulePattern{{pattern: "foo*", level: 2}, {pattern: "bar.go", level: 3}}
+	if len(patterns) != len(want) {
+		t.Fatalf("got %d patterns, want %d", len(patterns), len(want))
+	}
+	for i := range want {
+		if patterns[i] != want[i] {
+			t.Errorf("pattern[%d] = %+v, want %+v", i, patterns[i], want[i])
+		}
+	}
+
+	if _, err := parseVModule("missing-level"); err == nil {
+		t.Error("expected error for pattern missing '='")
+	}
+	if _, err := parseVModule("foo=notanumber"); err == nil {
+		t.Error("expected error for non-numeric level")
+	}
+}
+
+func TestVmoduleLevel(t *testing.T) {
+	t.Cleanup(func() { _ = SetVModule("") })
+
+	if err := SetVModule("special.go=5"); err != nil {
+		t.Fatalf("SetVModule: %v", err)
+	}
+
+	if level, found := vmoduleLevel("/some/path/special.go"); !found || level != 5 {
+		t.Errorf("vmoduleLevel(special.go) = (%d, %v), want (5, true)", level, found)
+	}
+
+	if level, found := vmoduleLevel("/some/path/other.go"); found {
+		t.Errorf("vmoduleLevel(other.go) = (%d, %v), want not found", level, found)
+	}
+}
+
+func TestSetVerbosityGatesV(t *testing.T) {
+	old := verbosity
+	t.Cleanup(func() { SetVerbosity(old) })
+
+	SetVerbosity(2)
+	if !V(2) {
+		t.Error("V(2) should be enabled at verbosity 2")
+	}
+	if V(3) {
+		t.Error("V(3) should be disabled at verbosity 2")
+	}
+}
+
+func TestEffectiveVLevelInvalidatesCacheOnSetVModule(t *testing.T) {
+	old := verbosity
+	t.Cleanup(func() {
+		SetVerbosity(old)
+		_ = SetVModule("")
+	})
+	SetVerbosity(0)
+
+	pc, _, _, ok := runtime.Caller(0)
+	if !ok {
+		t.Fatal("could not resolve a program counter for this test")
+	}
+
+	if got := effectiveVLevel(pc); got != 0 {
+		t.Fatalf("effectiveVLevel before override = %d, want 0", got)
+	}
+
+	if err := SetVModule("vlevel_test.go=4"); err != nil {
+		t.Fatalf("SetVModule: %v", err)
+	}
+	if got := effectiveVLevel(pc); got != 4 {
+		t.Errorf("effectiveVLevel after SetVModule = %d, want 4 (cache should invalidate on generation bump)", got)
+	}
+}